@@ -0,0 +1,112 @@
+package config
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+// AdminHandler serves the config KV surface (get/set/del/history/restore) over HTTP, gated by
+// AdminPassword sent as a Bearer token. Its routes are the full paths /admin/config/kv,
+// /admin/config/history and /admin/config/restore, so mount it where requests arrive with those
+// paths unchanged, e.g. mux.Handle("/admin/config/", cfg.AdminHandler()).
+func (c *Config) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/config/kv", c.handleKV)
+	mux.HandleFunc("/admin/config/history", c.handleHistory)
+	mux.HandleFunc("/admin/config/restore", c.handleRestore)
+	return c.requireAdminPassword(mux)
+}
+
+func (c *Config) requireAdminPassword(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		password, err := c.AdminPassword.Resolve()
+		if err != nil || password == "" {
+			http.Error(w, "admin endpoint is not configured", http.StatusServiceUnavailable)
+			return
+		}
+		token := r.Header.Get("Authorization")
+		if len(token) < 7 || token[:7] != "Bearer " ||
+			subtle.ConstantTimeCompare([]byte(token[7:]), []byte(password)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *Config) handleKV(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		http.Error(w, "missing key", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, err := c.Get(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]string{"key": key, "value": value})
+	case http.MethodPost, http.MethodPut:
+		var body struct {
+			Value string `json:"value"`
+		}
+		// Value travels in the JSON body, not the query string, so it doesn't end up in proxy or
+		// access logs the way a query parameter would.
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := c.Set(key, body.Value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]string{"key": key, "status": "ok"})
+	case http.MethodDelete:
+		if err := c.Del(key); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]string{"key": key, "status": "deleted"})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (c *Config) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	revisions, err := c.History()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, revisions)
+}
+
+func (c *Config) handleRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	revisionID := r.URL.Query().Get("revision_id")
+	if revisionID == "" {
+		http.Error(w, "missing revision_id", http.StatusBadRequest)
+		return
+	}
+	if err := c.Restore(revisionID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]string{"revision_id": revisionID, "status": "restored"})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
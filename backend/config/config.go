@@ -3,14 +3,18 @@ package config
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Log           LogConfig   `mapstructure:"log"`
 	HTTP          HTTPConfig  `mapstructure:"http"`
-	AdminPassword string      `mapstructure:"admin_password"`
+	AdminPassword SecretRef   `mapstructure:"admin_password"`
 	PG            PGConfig    `mapstructure:"pg"`
 	MQ            MQConfig    `mapstructure:"mq"`
 	RAG           RAGConfig   `mapstructure:"rag"`
@@ -19,6 +23,29 @@ type Config struct {
 	S3            S3Config    `mapstructure:"s3"`
 	CaddyAPI      string      `mapstructure:"caddy_api"`
 	SubnetPrefix  string      `mapstructure:"subnet_prefix"`
+
+	mu   sync.RWMutex
+	subs []*configSubscriber
+
+	// writeMu serializes the whole read-clone-mutate-validate-persist-swap sequence in commit
+	// and reload, so a Set/Del/Restore racing another one (or an fsnotify-triggered reload) can't
+	// clone the same starting state and silently drop one side's change. c.mu alone only protects
+	// the clone and the final swap, not the sequence as a whole.
+	writeMu sync.Mutex
+
+	// secretRefs holds the last unresolved value seen for each secretKeyPaths entry (a literal or
+	// a file://, env://, vault:// or awssm:// reference), keyed by dotted path. Populated by
+	// resolveSecrets/captureSecretRefs; used by persistConfigFile so writes to disk never leak an
+	// already-resolved secret for a key the caller didn't touch.
+	secretRefs map[string]string
+}
+
+// configSubscriber is notified with the reloaded Config whenever its watched section changes.
+// removed marks subscribers that called their unsubscribe func, so they're skipped on the next reload.
+type configSubscriber struct {
+	section string
+	fn      func(*Config)
+	removed bool
 }
 
 type LogConfig struct {
@@ -30,7 +57,7 @@ type HTTPConfig struct {
 }
 
 type PGConfig struct {
-	DSN string `mapstructure:"dsn"`
+	DSN SecretRef `mapstructure:"dsn"`
 }
 
 type MQConfig struct {
@@ -39,24 +66,21 @@ type MQConfig struct {
 }
 
 type NATSConfig struct {
-	Server   string `mapstructure:"server"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
+	Server   string    `mapstructure:"server"`
+	User     string    `mapstructure:"user"`
+	Password SecretRef `mapstructure:"password"`
 }
 
+// RAGConfig selects a registered RAGProvider by name and carries its settings as a raw map,
+// decoded into the provider's own config struct by its factory. See RegisterRAGProvider.
 type RAGConfig struct {
-	Provider string      `mapstructure:"provider"`
-	CTRAG    CTRAGConfig `mapstructure:"ct_rag"`
-}
-
-type CTRAGConfig struct {
-	BaseURL string `mapstructure:"base_url"`
-	APIKey  string `mapstructure:"api_key"`
+	Provider string         `mapstructure:"provider"`
+	Settings map[string]any `mapstructure:"settings"`
 }
 
 type RedisConfig struct {
-	Addr     string `mapstructure:"addr"`
-	Password string `mapstructure:"password"`
+	Addr     string    `mapstructure:"addr"`
+	Password SecretRef `mapstructure:"password"`
 }
 
 type AuthConfig struct {
@@ -65,13 +89,13 @@ type AuthConfig struct {
 }
 
 type JWTConfig struct {
-	Secret string `mapstructure:"secret"`
+	Secret SecretRef `mapstructure:"secret"`
 }
 
 type S3Config struct {
-	Endpoint  string `mapstructure:"endpoint"`
-	AccessKey string `mapstructure:"access_key"`
-	SecretKey string `mapstructure:"secret_key"`
+	Endpoint  string    `mapstructure:"endpoint"`
+	AccessKey string    `mapstructure:"access_key"`
+	SecretKey SecretRef `mapstructure:"secret_key"`
 }
 
 // getMinioEndpoint returns minio endpoint from environment variable or default
@@ -139,7 +163,7 @@ func NewConfig() (*Config, error) {
 			Port: 8000,
 		},
 		PG: PGConfig{
-			DSN: fmt.Sprintf("host=%s user=panda-wiki password=panda-wiki-secret dbname=panda-wiki port=5432 sslmode=disable TimeZone=Asia/Shanghai", getPostgresHost()),
+			DSN: SecretRef(fmt.Sprintf("host=%s user=panda-wiki password=panda-wiki-secret dbname=panda-wiki port=5432 sslmode=disable TimeZone=Asia/Shanghai", getPostgresHost())),
 		},
 		MQ: MQConfig{
 			Type: "nats",
@@ -151,9 +175,9 @@ func NewConfig() (*Config, error) {
 		},
 		RAG: RAGConfig{
 			Provider: "ct",
-			CTRAG: CTRAGConfig{
-				BaseURL: getRagBaseURL(),
-				APIKey:  "sk-1234567890",
+			Settings: map[string]any{
+				"base_url": getRagBaseURL(),
+				"api_key":  "sk-1234567890",
 			},
 		},
 		Redis: RedisConfig{
@@ -178,6 +202,12 @@ func NewConfig() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yml")
 
+	// every Config field is also readable as PANDAWIKI_<PATH>, e.g. pg.dsn -> PANDAWIKI_PG_DSN
+	viper.SetEnvPrefix("PANDAWIKI")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	bindEnvVars(reflect.TypeOf(Config{}), nil)
+
 	// try to read config file
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -186,43 +216,166 @@ func NewConfig() (*Config, error) {
 		}
 	}
 
-	// merge config file values to default config
+	// merge config file and PANDAWIKI_ env values into default config
 	if err := viper.Unmarshal(defaultConfig); err != nil {
 		return nil, err
 	}
 
-	// finally, override sensitive info with env variables
+	// finally, override sensitive info with the legacy env variables kept for backward compatibility
 	overrideWithEnv(defaultConfig)
 
+	if err := defaultConfig.resolveSecrets(); err != nil {
+		return nil, fmt.Errorf("resolving secrets:\n%w", err)
+	}
+
+	if err := defaultConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	viper.WatchConfig()
+	viper.OnConfigChange(func(fsnotify.Event) {
+		defaultConfig.reload()
+	})
+
 	return defaultConfig, nil
 }
 
-// overrideWithEnv override sensitive info with env variables
+// Subscribe registers fn to be called with the reloaded Config whenever the top-level section
+// named by section (its mapstructure tag, e.g. "rag" or "redis") changes on a config.yml reload.
+// Call the returned unsubscribe func to stop receiving notifications.
+func (c *Config) Subscribe(section string, fn func(*Config)) (unsubscribe func()) {
+	c.mu.Lock()
+	sub := &configSubscriber{section: section, fn: fn}
+	c.subs = append(c.subs, sub)
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		sub.removed = true
+		c.mu.Unlock()
+	}
+}
+
+// reload re-reads config.yml plus env overrides, swaps the new values into c, and notifies
+// subscribers whose section actually changed. It's wired up as the viper.OnConfigChange handler.
+// It takes c.writeMu for its whole run, the same lock commit holds, so a file-driven reload can't
+// race an admin Set/Del/Restore and silently lose one side's change.
+func (c *Config) reload() {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	next := &Config{}
+	if err := viper.Unmarshal(next); err != nil {
+		return
+	}
+	overrideWithEnv(next)
+	if err := next.resolveSecrets(); err != nil {
+		return
+	}
+	if err := next.Validate(); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	changed := changedSections(c, next)
+	copySections(next, c)
+	c.secretRefs = next.secretRefs
+	subs := append([]*configSubscriber{}, c.subs...)
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		if sub.removed {
+			continue
+		}
+		for _, section := range changed {
+			if sub.section == section {
+				sub.fn(c)
+				break
+			}
+		}
+	}
+}
+
+// changedSections compares the top-level fields of old and next by their mapstructure tag and
+// returns the tags of the ones that differ, via reflect.DeepEqual on each sub-struct.
+func changedSections(old, next *Config) []string {
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(next).Elem()
+	t := ov.Type()
+
+	var changed []string
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("mapstructure")
+		if tag == "" {
+			continue
+		}
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			changed = append(changed, tag)
+		}
+	}
+	return changed
+}
+
+// copySections copies every mapstructure-tagged field from next into dst, leaving dst's mutex
+// and subscriber list untouched.
+func copySections(next, dst *Config) {
+	v := reflect.ValueOf(next).Elem()
+	t := v.Type()
+	dv := reflect.ValueOf(dst).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == "" {
+			continue
+		}
+		dv.Field(i).Set(v.Field(i))
+	}
+}
+
+// bindEnvVars walks t recursively and binds every leaf mapstructure path (e.g. "mq.nats.password")
+// to viper, so it participates in AutomaticEnv resolution as PANDAWIKI_MQ_NATS_PASSWORD without
+// needing to be listed by hand.
+func bindEnvVars(t reflect.Type, prefix []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		path := append(append([]string{}, prefix...), tag)
+		if field.Type.Kind() == reflect.Struct {
+			bindEnvVars(field.Type, path)
+			continue
+		}
+		_ = viper.BindEnv(strings.Join(path, "."))
+	}
+}
+
+// overrideWithEnv overrides sensitive info with the legacy, pre-PANDAWIKI_ env variables.
+// These are kept as explicit aliases for backward compatibility; new config knobs don't need
+// an entry here since bindEnvVars already exposes them as PANDAWIKI_<PATH>.
 func overrideWithEnv(c *Config) {
 	if env := os.Getenv("POSTGRES_PASSWORD"); env != "" {
-		c.PG.DSN = fmt.Sprintf("host=%s user=panda-wiki password=%s dbname=panda-wiki port=5432 sslmode=disable TimeZone=Asia/Shanghai", getPostgresHost(), env)
+		c.PG.DSN = SecretRef(fmt.Sprintf("host=%s user=panda-wiki password=%s dbname=panda-wiki port=5432 sslmode=disable TimeZone=Asia/Shanghai", getPostgresHost(), env))
 	}
 	if env := os.Getenv("NATS_PASSWORD"); env != "" {
-		c.MQ.NATS.Password = env
+		c.MQ.NATS.Password = SecretRef(env)
 	}
 	if env := os.Getenv("REDIS_PASSWORD"); env != "" {
-		c.Redis.Password = env
+		c.Redis.Password = SecretRef(env)
 	}
 	if env := os.Getenv("JWT_SECRET"); env != "" {
-		c.Auth.JWT.Secret = env
+		c.Auth.JWT.Secret = SecretRef(env)
 	}
 	if env := os.Getenv("S3_SECRET_KEY"); env != "" {
-		c.S3.SecretKey = env
+		c.S3.SecretKey = SecretRef(env)
 	}
 	if env := os.Getenv("ADMIN_PASSWORD"); env != "" {
-		c.AdminPassword = env
+		c.AdminPassword = SecretRef(env)
 	}
 	if env := os.Getenv("SUBNET_PREFIX"); env != "" {
 		c.SubnetPrefix = env
 	}
 	// pg
 	if env := os.Getenv("PG_DSN"); env != "" {
-		c.PG.DSN = env
+		c.PG.DSN = SecretRef(env)
 	}
 	// nats
 	if env := os.Getenv("MQ_NATS_SERVER"); env != "" {
@@ -230,7 +383,10 @@ func overrideWithEnv(c *Config) {
 	}
 	// rag
 	if env := os.Getenv("RAG_CT_RAG_BASE_URL"); env != "" {
-		c.RAG.CTRAG.BaseURL = env
+		if c.RAG.Settings == nil {
+			c.RAG.Settings = map[string]any{}
+		}
+		c.RAG.Settings["base_url"] = env
 	}
 	// redis
 	if env := os.Getenv("REDIS_ADDR"); env != "" {
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// chdirTemp changes the working directory to a fresh t.TempDir() for the duration of the test, so
+// tests exercising commit's config/config.yml persistence don't read or write this package's own
+// directory. Restored automatically via t.Cleanup.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatalf("restoring Chdir: %v", err)
+		}
+	})
+}
+
+// TestNewConfig_EnvBinding verifies bindEnvVars's reflection walk exposes a deeply nested field
+// as PANDAWIKI_<PATH> without needing an explicit BindEnv call for it.
+func TestNewConfig_EnvBinding(t *testing.T) {
+	viper.Reset()
+	t.Setenv("PANDAWIKI_MQ_NATS_PASSWORD", "nats-env-secret")
+	t.Setenv("PANDAWIKI_AUTH_JWT_SECRET", "jwt-env-secret")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if got := string(cfg.MQ.NATS.Password); got != "nats-env-secret" {
+		t.Errorf("MQ.NATS.Password = %q, want %q", got, "nats-env-secret")
+	}
+}
+
+// TestNewConfig_LegacyEnvAlias verifies overrideWithEnv still honors the pre-PANDAWIKI_ env names
+// kept for backward compatibility.
+func TestNewConfig_LegacyEnvAlias(t *testing.T) {
+	viper.Reset()
+	t.Setenv("JWT_SECRET", "legacy-jwt-secret")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if got := string(cfg.Auth.JWT.Secret); got != "legacy-jwt-secret" {
+		t.Errorf("Auth.JWT.Secret = %q, want %q", got, "legacy-jwt-secret")
+	}
+}
+
+// TestConfig_Subscribe verifies Subscribe is notified only for the section it watches, and that
+// the returned unsubscribe func stops further notifications.
+func TestConfig_Subscribe(t *testing.T) {
+	chdirTemp(t)
+
+	c := validConfig()
+
+	var mu sync.Mutex
+	var httpFired, redisFired int
+
+	unsubHTTP := c.Subscribe("http", func(*Config) {
+		mu.Lock()
+		httpFired++
+		mu.Unlock()
+	})
+	c.Subscribe("redis", func(*Config) {
+		mu.Lock()
+		redisFired++
+		mu.Unlock()
+	})
+
+	if err := c.Set("http.port", "9001"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	mu.Lock()
+	if httpFired != 1 {
+		t.Errorf("httpFired = %d, want 1 after changing http.port", httpFired)
+	}
+	if redisFired != 0 {
+		t.Errorf("redisFired = %d, want 0 after changing http.port", redisFired)
+	}
+	mu.Unlock()
+
+	unsubHTTP()
+
+	if err := c.Set("http.port", "9002"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if httpFired != 1 {
+		t.Errorf("httpFired = %d, want 1 after unsubscribing", httpFired)
+	}
+}
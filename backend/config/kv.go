@@ -0,0 +1,402 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFilePath   = "config/config.yml"
+	configHistoryDir = "config/history"
+	maxConfigHistory = 20
+)
+
+// Revision is one snapshot in the config history, named after the moment it was taken.
+type Revision struct {
+	ID   string
+	Time time.Time
+}
+
+// Get returns the string representation of the dotted config key (e.g. "rag.provider",
+// "rag.settings.base_url") from the live, in-memory config. A key in secretKeyPaths is returned
+// masked as "***", the same as PrintConfigMasked, instead of the resolved secret.
+func (c *Config) Get(key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	v, err := getField(reflect.ValueOf(c).Elem(), strings.Split(key, "."))
+	if err != nil {
+		return "", err
+	}
+	if isSecretKey(key) {
+		return "***", nil
+	}
+	return fmt.Sprintf("%v", v.Interface()), nil
+}
+
+// Set writes value to the dotted config key, validates the resulting config, and on success
+// persists it to config/config.yml (snapshotting the previous file into config/history first)
+// and swaps it into the live config, notifying Subscribe-ers of any changed section.
+func (c *Config) Set(key, value string) error {
+	return c.commit(func(next *Config) error {
+		if err := setField(reflect.ValueOf(next).Elem(), strings.Split(key, "."), value); err != nil {
+			return err
+		}
+		if isSecretKey(key) {
+			next.secretRefs[key] = value
+		}
+		return nil
+	})
+}
+
+// Del resets the dotted config key to its zero value and commits the result the same way Set does.
+func (c *Config) Del(key string) error {
+	return c.commit(func(next *Config) error {
+		if err := delField(reflect.ValueOf(next).Elem(), strings.Split(key, ".")); err != nil {
+			return err
+		}
+		if isSecretKey(key) {
+			delete(next.secretRefs, key)
+		}
+		return nil
+	})
+}
+
+// History lists the config snapshots under config/history, most recent first.
+func (c *Config) History() ([]Revision, error) {
+	entries, err := os.ReadDir(configHistoryDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	revisions := make([]Revision, 0, len(entries))
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		t, err := time.Parse(historyTimeLayout, id)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, Revision{ID: id, Time: t})
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Time.After(revisions[j].Time) })
+	return revisions, nil
+}
+
+// Restore replaces the live and on-disk config with the named history revision, after
+// validating it the same way Set/Del do. The current config is itself snapshotted first, so a
+// bad restore can always be undone.
+func (c *Config) Restore(revisionID string) error {
+	data, err := os.ReadFile(filepath.Join(configHistoryDir, revisionID+".yml"))
+	if err != nil {
+		return fmt.Errorf("config: read revision %q: %w", revisionID, err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("config: parse revision %q: %w", revisionID, err)
+	}
+	next := &Config{}
+	if err := decodeRAGSettings(raw, next); err != nil {
+		return fmt.Errorf("config: decode revision %q: %w", revisionID, err)
+	}
+	restoredRefs := captureSecretRefs(next)
+
+	return c.commit(func(dst *Config) error {
+		copySections(next, dst)
+		dst.secretRefs = restoredRefs
+		return nil
+	})
+}
+
+// commit runs mutate against a clone of the live config, validates and persists it, and on
+// success swaps it in, notifying Subscribe-ers of changed sections. It holds c.writeMu for the
+// whole sequence, so two concurrent commits (or a commit racing a reload) can't both clone the
+// same starting state and have the loser silently overwrite the winner's change.
+func (c *Config) commit(mutate func(*Config) error) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.mu.Lock()
+	next := &Config{}
+	copySections(c, next)
+	next.RAG.Settings = maps.Clone(c.RAG.Settings)
+	next.secretRefs = maps.Clone(c.secretRefs)
+	c.mu.Unlock()
+	if next.secretRefs == nil {
+		next.secretRefs = map[string]string{}
+	}
+
+	if err := mutate(next); err != nil {
+		return err
+	}
+	if err := next.Validate(); err != nil {
+		return fmt.Errorf("config: refusing to write invalid config:\n%w", err)
+	}
+	if err := persistConfigFile(next); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	changed := changedSections(c, next)
+	copySections(next, c)
+	c.secretRefs = next.secretRefs
+	subs := append([]*configSubscriber{}, c.subs...)
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		if sub.removed {
+			continue
+		}
+		for _, section := range changed {
+			if sub.section == section {
+				sub.fn(c)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// persistConfigFile snapshots the current config/config.yml into config/history, then atomically
+// replaces it with next via a temp-file-plus-rename.
+func persistConfigFile(next *Config) error {
+	if err := snapshotConfigHistory(); err != nil {
+		return fmt.Errorf("config: snapshot history: %w", err)
+	}
+
+	data, err := yaml.Marshal(configForPersist(next))
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(configFilePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "config-*.yml.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, configFilePath)
+}
+
+const historyTimeLayout = "20060102-150405.000"
+
+func snapshotConfigHistory() error {
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(configHistoryDir, 0o755); err != nil {
+		return err
+	}
+	name := filepath.Join(configHistoryDir, time.Now().Format(historyTimeLayout)+".yml")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		return err
+	}
+	return pruneConfigHistory()
+}
+
+func pruneConfigHistory() error {
+	entries, err := os.ReadDir(configHistoryDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) <= maxConfigHistory {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, entry := range entries[:len(entries)-maxConfigHistory] {
+		if err := os.Remove(filepath.Join(configHistoryDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configToMap renders c into a map keyed by its mapstructure tags, suitable for yaml.Marshal
+// into config.yml (which viper reads back the same way it reads a hand-written file).
+func configToMap(c *Config) map[string]any {
+	return structToMap(reflect.ValueOf(c).Elem())
+}
+
+// configForPersist renders next the way configToMap does, but with every secretKeyPaths entry
+// overlaid from next.secretRefs instead of next's own (already-resolved) field value. Without
+// this, persisting an unrelated key change would write every live, resolved secret to
+// config/config.yml in plaintext.
+func configForPersist(next *Config) map[string]any {
+	m := configToMap(next)
+	if rag, ok := m["rag"].(map[string]any); ok {
+		if settings, ok := rag["settings"].(map[string]any); ok {
+			rag["settings"] = maps.Clone(settings)
+		}
+	}
+	for _, key := range secretKeyPaths {
+		if ref, ok := next.secretRefs[key]; ok {
+			setMapPath(m, strings.Split(key, "."), ref)
+		}
+	}
+	return m
+}
+
+// setMapPath assigns value at the nested map path, where every element but the last must already
+// exist as a map[string]any (true for anything configToMap produced).
+func setMapPath(m map[string]any, path []string, value string) {
+	for _, segment := range path[:len(path)-1] {
+		next, ok := m[segment].(map[string]any)
+		if !ok {
+			return
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+func structToMap(v reflect.Value) map[string]any {
+	t := v.Type()
+	out := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("mapstructure")
+		if tag == "" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			out[tag] = structToMap(fv)
+		} else {
+			out[tag] = fv.Interface()
+		}
+	}
+	return out
+}
+
+// getField walks v by path, where each struct level is matched by its mapstructure tag and a
+// map level (RAG.Settings) is matched by the remaining path joined back into one key.
+func getField(v reflect.Value, path []string) (reflect.Value, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	if v.Kind() == reflect.Map {
+		mv := v.MapIndex(reflect.ValueOf(strings.Join(path, ".")))
+		if !mv.IsValid() {
+			return reflect.Value{}, fmt.Errorf("config: key %q not found", strings.Join(path, "."))
+		}
+		return mv.Elem(), nil
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("config: %q does not have nested keys", strings.Join(path, "."))
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == path[0] {
+			return getField(v.Field(i), path[1:])
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("config: unknown key %q", strings.Join(path, "."))
+}
+
+func setField(v reflect.Value, path []string, raw string) error {
+	if v.Kind() == reflect.Map {
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(reflect.ValueOf(strings.Join(path, ".")), reflect.ValueOf(any(raw)))
+		return nil
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("config: %q does not have nested keys", strings.Join(path, "."))
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") != path[0] {
+			continue
+		}
+		fv := v.Field(i)
+		if len(path) == 1 {
+			return parseInto(fv, raw)
+		}
+		return setField(fv, path[1:], raw)
+	}
+	return fmt.Errorf("config: unknown key %q", strings.Join(path, "."))
+}
+
+func delField(v reflect.Value, path []string) error {
+	if v.Kind() == reflect.Map {
+		key := reflect.ValueOf(strings.Join(path, "."))
+		if !v.MapIndex(key).IsValid() {
+			return fmt.Errorf("config: key %q not found", strings.Join(path, "."))
+		}
+		v.SetMapIndex(key, reflect.Value{})
+		return nil
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("config: %q does not have nested keys", strings.Join(path, "."))
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") != path[0] {
+			continue
+		}
+		fv := v.Field(i)
+		if len(path) == 1 {
+			fv.Set(reflect.Zero(fv.Type()))
+			return nil
+		}
+		return delField(fv, path[1:])
+	}
+	return fmt.Errorf("config: unknown key %q", strings.Join(path, "."))
+}
+
+// parseInto assigns raw into fv, converting it to fv's underlying kind. fv may be a named
+// string type (SecretRef); settings-map entries are stored by setField directly, not through here.
+func parseInto(fv reflect.Value, raw string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("config: %q is not an integer", raw)
+		}
+		fv.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("config: %q is not a bool", raw)
+		}
+		fv.SetBool(b)
+	case reflect.Interface:
+		fv.Set(reflect.ValueOf(any(raw)))
+	default:
+		return fmt.Errorf("config: unsupported field kind %s", fv.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConfig_commit_concurrent fires two concurrent Sets at different keys and checks both land,
+// guarding against the lost-update race where a slower writer clones a stale starting state and
+// overwrites the faster one's change.
+func TestConfig_commit_concurrent(t *testing.T) {
+	chdirTemp(t)
+
+	c := validConfig()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		errs <- c.Set("http.port", "9001")
+	}()
+	go func() {
+		defer wg.Done()
+		errs <- c.Set("caddy_api", "/tmp/other-caddy.sock")
+	}()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+
+	if c.HTTP.Port != 9001 {
+		t.Errorf("HTTP.Port = %d, want 9001 (lost update)", c.HTTP.Port)
+	}
+	if c.CaddyAPI != "/tmp/other-caddy.sock" {
+		t.Errorf("CaddyAPI = %q, want %q (lost update)", c.CaddyAPI, "/tmp/other-caddy.sock")
+	}
+}
+
+// TestConfig_Get_masksSecrets verifies Get never returns a resolved secret for a secretKeyPaths
+// key, matching the masking PrintConfigMasked already applies.
+func TestConfig_Get_masksSecrets(t *testing.T) {
+	c := validConfig()
+
+	for _, key := range secretKeyPaths {
+		if key == "rag.settings.api_key" {
+			// not set on validConfig's bare RAGConfig; Get would 404 rather than mask.
+			continue
+		}
+		got, err := c.Get(key)
+		if err != nil {
+			t.Errorf("Get(%q): %v", key, err)
+			continue
+		}
+		if got != "***" {
+			t.Errorf("Get(%q) = %q, want the masked value \"***\"", key, got)
+		}
+	}
+
+	if got, err := c.Get("http.port"); err != nil || got == "***" {
+		t.Errorf("Get(\"http.port\") = (%q, %v), want the real, unmasked value", got, err)
+	}
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// RAGProvider is the backend a PandaWiki instance talks to for embedding, reranking, retrieval
+// and ingestion. Swapping RAG.Provider in config.yml swaps the implementation without a rebuild.
+type RAGProvider interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Rerank(ctx context.Context, query string, docs []string) ([]int, error)
+	Retrieve(ctx context.Context, query string, topK int) ([]string, error)
+	Ingest(ctx context.Context, docs []string) error
+}
+
+// RAGProviderFactory builds a RAGProvider from RAGConfig.Settings.
+type RAGProviderFactory func(raw map[string]any) (RAGProvider, error)
+
+var (
+	ragProvidersMu sync.RWMutex
+	ragProviders   = map[string]RAGProviderFactory{}
+)
+
+// RegisterRAGProvider makes a RAGProvider available under name for RAGConfig.Provider to select.
+// It's meant to be called from an init() func, mirroring database/sql's driver registry; it
+// panics on a nil factory or a name registered twice.
+func RegisterRAGProvider(name string, factory RAGProviderFactory) {
+	if factory == nil {
+		panic("config: RegisterRAGProvider factory is nil for " + name)
+	}
+
+	ragProvidersMu.Lock()
+	defer ragProvidersMu.Unlock()
+	if _, dup := ragProviders[name]; dup {
+		panic("config: RegisterRAGProvider called twice for provider " + name)
+	}
+	ragProviders[name] = factory
+}
+
+// NewRAGProvider builds the RAGProvider selected by RAG.Provider, decoding RAG.Settings into
+// whatever config struct that provider's factory expects.
+func (c *Config) NewRAGProvider() (RAGProvider, error) {
+	ragProvidersMu.RLock()
+	factory, ok := ragProviders[c.RAG.Provider]
+	ragProvidersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("config: unknown rag provider %q", c.RAG.Provider)
+	}
+	return factory(c.RAG.Settings)
+}
+
+// decodeRAGSettings is a small wrapper around mapstructure so provider factories don't each
+// need to import it directly.
+func decodeRAGSettings(raw map[string]any, out any) error {
+	return mapstructure.Decode(raw, out)
+}
+
+func init() {
+	RegisterRAGProvider("ct", newCTRAGProvider)
+	RegisterRAGProvider("openai", newOpenAIRAGProvider)
+	RegisterRAGProvider("ollama", newOllamaRAGProvider)
+}
+
+// ctRAGConfig is the settings shape for the "ct" provider, PandaWiki's original/default
+// in-house RAG backend.
+type ctRAGConfig struct {
+	BaseURL string `mapstructure:"base_url"`
+	APIKey  string `mapstructure:"api_key"`
+}
+
+type ctRAGProvider struct {
+	cfg ctRAGConfig
+}
+
+func newCTRAGProvider(raw map[string]any) (RAGProvider, error) {
+	var cfg ctRAGConfig
+	if err := decodeRAGSettings(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("config: decode ct rag settings: %w", err)
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("config: ct rag provider requires settings.base_url")
+	}
+	return &ctRAGProvider{cfg: cfg}, nil
+}
+
+func (p *ctRAGProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var out [][]float32
+	err := p.post(ctx, "/embed", map[string]any{"texts": texts}, &struct {
+		Embeddings *[][]float32 `json:"embeddings"`
+	}{Embeddings: &out})
+	return out, err
+}
+
+func (p *ctRAGProvider) Rerank(ctx context.Context, query string, docs []string) ([]int, error) {
+	var out []int
+	err := p.post(ctx, "/rerank", map[string]any{"query": query, "docs": docs}, &struct {
+		Order *[]int `json:"order"`
+	}{Order: &out})
+	return out, err
+}
+
+func (p *ctRAGProvider) Retrieve(ctx context.Context, query string, topK int) ([]string, error) {
+	var out []string
+	err := p.post(ctx, "/retrieve", map[string]any{"query": query, "top_k": topK}, &struct {
+		Docs *[]string `json:"docs"`
+	}{Docs: &out})
+	return out, err
+}
+
+func (p *ctRAGProvider) Ingest(ctx context.Context, docs []string) error {
+	return p.post(ctx, "/ingest", map[string]any{"docs": docs}, &struct{}{})
+}
+
+func (p *ctRAGProvider) post(ctx context.Context, path string, body, out any) error {
+	return postJSON(ctx, p.cfg.BaseURL+path, p.cfg.APIKey, body, out)
+}
+
+// openAIRAGConfig is the settings shape for any OpenAI-compatible embeddings/chat endpoint
+// (OpenAI itself, or a self-hosted gateway that speaks the same API).
+type openAIRAGConfig struct {
+	BaseURL string `mapstructure:"base_url"`
+	APIKey  string `mapstructure:"api_key"`
+	Model   string `mapstructure:"model"`
+}
+
+type openAIRAGProvider struct {
+	cfg openAIRAGConfig
+}
+
+func newOpenAIRAGProvider(raw map[string]any) (RAGProvider, error) {
+	var cfg openAIRAGConfig
+	if err := decodeRAGSettings(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("config: decode openai rag settings: %w", err)
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.openai.com/v1"
+	}
+	if cfg.Model == "" {
+		cfg.Model = "text-embedding-3-small"
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("config: openai rag provider requires settings.api_key")
+	}
+	return &openAIRAGProvider{cfg: cfg}, nil
+}
+
+func (p *openAIRAGProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	err := postJSON(ctx, p.cfg.BaseURL+"/embeddings", p.cfg.APIKey, map[string]any{
+		"model": p.cfg.Model,
+		"input": texts,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]float32, len(resp.Data))
+	for i, d := range resp.Data {
+		out[i] = d.Embedding
+	}
+	return out, nil
+}
+
+func (p *openAIRAGProvider) Rerank(ctx context.Context, query string, docs []string) ([]int, error) {
+	return nil, fmt.Errorf("config: openai rag provider does not support rerank")
+}
+
+func (p *openAIRAGProvider) Retrieve(ctx context.Context, query string, topK int) ([]string, error) {
+	return nil, fmt.Errorf("config: openai rag provider does not support retrieve, pair it with a vector store")
+}
+
+func (p *openAIRAGProvider) Ingest(ctx context.Context, docs []string) error {
+	return fmt.Errorf("config: openai rag provider does not support ingest, pair it with a vector store")
+}
+
+// ollamaRAGConfig is the settings shape for a local Ollama instance used as an embedding (and
+// optionally rerank) backend.
+type ollamaRAGConfig struct {
+	EmbedURL  string `mapstructure:"embed_url"`
+	RerankURL string `mapstructure:"rerank_url"`
+	Model     string `mapstructure:"model"`
+}
+
+type ollamaRAGProvider struct {
+	cfg ollamaRAGConfig
+}
+
+func newOllamaRAGProvider(raw map[string]any) (RAGProvider, error) {
+	var cfg ollamaRAGConfig
+	if err := decodeRAGSettings(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("config: decode ollama rag settings: %w", err)
+	}
+	if cfg.EmbedURL == "" {
+		cfg.EmbedURL = "http://localhost:11434/api/embeddings"
+	}
+	if cfg.Model == "" {
+		cfg.Model = "nomic-embed-text"
+	}
+	return &ollamaRAGProvider{cfg: cfg}, nil
+}
+
+func (p *ollamaRAGProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		var resp struct {
+			Embedding []float32 `json:"embedding"`
+		}
+		if err := postJSON(ctx, p.cfg.EmbedURL, "", map[string]any{
+			"model":  p.cfg.Model,
+			"prompt": text,
+		}, &resp); err != nil {
+			return nil, err
+		}
+		out[i] = resp.Embedding
+	}
+	return out, nil
+}
+
+func (p *ollamaRAGProvider) Rerank(ctx context.Context, query string, docs []string) ([]int, error) {
+	if p.cfg.RerankURL == "" {
+		return nil, fmt.Errorf("config: ollama rag provider requires settings.rerank_url for rerank")
+	}
+	var out []int
+	err := postJSON(ctx, p.cfg.RerankURL, "", map[string]any{"query": query, "docs": docs}, &struct {
+		Order *[]int `json:"order"`
+	}{Order: &out})
+	return out, err
+}
+
+func (p *ollamaRAGProvider) Retrieve(ctx context.Context, query string, topK int) ([]string, error) {
+	return nil, fmt.Errorf("config: ollama rag provider does not support retrieve, pair it with a vector store")
+}
+
+func (p *ollamaRAGProvider) Ingest(ctx context.Context, docs []string) error {
+	return fmt.Errorf("config: ollama rag provider does not support ingest, pair it with a vector store")
+}
+
+// postJSON POSTs body as JSON to url, decoding the JSON response into out. An empty apiKey
+// omits the Authorization header.
+func postJSON(ctx context.Context, url, apiKey string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("config: rag request to %s failed: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
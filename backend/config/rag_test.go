@@ -0,0 +1,62 @@
+package config
+
+import "testing"
+
+func TestRegisterRAGProvider(t *testing.T) {
+	t.Run("built-ins are registered", func(t *testing.T) {
+		for _, name := range []string{"ct", "openai", "ollama"} {
+			ragProvidersMu.RLock()
+			_, ok := ragProviders[name]
+			ragProvidersMu.RUnlock()
+			if !ok {
+				t.Errorf("provider %q is not registered", name)
+			}
+		}
+	})
+
+	t.Run("nil factory panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("RegisterRAGProvider did not panic on a nil factory")
+			}
+		}()
+		RegisterRAGProvider("test-nil-factory", nil)
+	})
+
+	t.Run("duplicate name panics", func(t *testing.T) {
+		RegisterRAGProvider("test-duplicate", func(map[string]any) (RAGProvider, error) { return nil, nil })
+		defer func() {
+			if recover() == nil {
+				t.Error("RegisterRAGProvider did not panic on a duplicate name")
+			}
+		}()
+		RegisterRAGProvider("test-duplicate", func(map[string]any) (RAGProvider, error) { return nil, nil })
+	})
+}
+
+func TestConfig_NewRAGProvider(t *testing.T) {
+	t.Run("unknown provider", func(t *testing.T) {
+		c := &Config{RAG: RAGConfig{Provider: "nonexistent"}}
+		if _, err := c.NewRAGProvider(); err == nil {
+			t.Error("NewRAGProvider() = nil error, want one for an unregistered provider")
+		}
+	})
+
+	t.Run("ct requires base_url", func(t *testing.T) {
+		c := &Config{RAG: RAGConfig{Provider: "ct", Settings: map[string]any{}}}
+		if _, err := c.NewRAGProvider(); err == nil {
+			t.Error("NewRAGProvider() = nil error, want one when settings.base_url is missing")
+		}
+	})
+
+	t.Run("ct with base_url", func(t *testing.T) {
+		c := &Config{RAG: RAGConfig{Provider: "ct", Settings: map[string]any{"base_url": "http://localhost:8080"}}}
+		p, err := c.NewRAGProvider()
+		if err != nil {
+			t.Fatalf("NewRAGProvider: %v", err)
+		}
+		if p == nil {
+			t.Error("NewRAGProvider() = nil provider, want a ctRAGProvider")
+		}
+	})
+}
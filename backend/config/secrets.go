@@ -0,0 +1,270 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretRef is a config value that may either be a literal secret or a reference to one held in
+// an external store, resolved by scheme: file://path, env://NAME, vault://path#field or
+// awssm://arn. A value with no recognized scheme is treated as a literal and returned as-is.
+type SecretRef string
+
+const secretCacheTTL = 30 * time.Second
+
+type secretCacheEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+var (
+	secretCacheMu sync.Mutex
+	secretCache   = map[SecretRef]secretCacheEntry{}
+)
+
+// Resolve dispatches s to the resolver matching its URI scheme, caching the result for
+// secretCacheTTL so repeated resolution (e.g. on every config reload) doesn't hammer the
+// backing store.
+func (s SecretRef) Resolve() (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	secretCacheMu.Lock()
+	if entry, ok := secretCache[s]; ok && time.Now().Before(entry.expiresAt) {
+		secretCacheMu.Unlock()
+		return entry.value, nil
+	}
+	secretCacheMu.Unlock()
+
+	scheme, _, ok := strings.Cut(string(s), "://")
+	var (
+		value string
+		err   error
+	)
+	switch {
+	case !ok:
+		return string(s), nil
+	case scheme == "file":
+		value, err = resolveFileSecret(string(s))
+	case scheme == "env":
+		value, err = resolveEnvSecret(string(s))
+	case scheme == "vault":
+		value, err = resolveVaultSecret(string(s))
+	case scheme == "awssm":
+		value, err = resolveAWSSMSecret(string(s))
+	default:
+		return string(s), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("config: resolve secret %q: %w", scheme+"://...", err)
+	}
+
+	secretCacheMu.Lock()
+	secretCache[s] = secretCacheEntry{value: value, expiresAt: time.Now().Add(secretCacheTTL)}
+	secretCacheMu.Unlock()
+
+	return value, nil
+}
+
+func resolveFileSecret(ref string) (string, error) {
+	path := strings.TrimPrefix(ref, "file://")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func resolveEnvSecret(ref string) (string, error) {
+	name := strings.TrimPrefix(ref, "env://")
+	return os.Getenv(name), nil
+}
+
+// resolveVaultSecret reads a field out of a HashiCorp Vault KV v2 secret, addressed as
+// vault://<mount>/<path>#<field>, using VAULT_ADDR and VAULT_TOKEN.
+func resolveVaultSecret(ref string) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN must be set to resolve a vault:// secret")
+	}
+
+	path, field, ok := strings.Cut(strings.TrimPrefix(ref, "vault://"), "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault secret ref %q must be vault://<path>#<field>", ref)
+	}
+	mount, subPath, _ := strings.Cut(path, "/")
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(addr, "/"), mount, subPath)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("vault request to %s failed: %s", url, resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	value, ok := body.Data.Data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no string field %q", path, field)
+	}
+	return value, nil
+}
+
+// resolveAWSSMSecret fetches a secret's value from AWS Secrets Manager, addressed as
+// awssm://<secret-id-or-arn>, using the default AWS credential chain.
+func resolveAWSSMSecret(ref string) (string, error) {
+	secretID := strings.TrimPrefix(ref, "awssm://")
+	if secretID == "" {
+		return "", fmt.Errorf("awssm secret ref %q must be awssm://<secret-id-or-arn>", ref)
+	}
+	return fetchAWSSecretValue(context.Background(), secretID)
+}
+
+// secretKeyPaths lists every dotted config key that holds a secret, in the same set resolveSecrets
+// resolves and captureSecretRefs captures. persistConfigFile uses this list to write back the
+// unresolved reference instead of whatever resolved value happens to be live in memory.
+var secretKeyPaths = []string{
+	"pg.dsn",
+	"mq.nats.password",
+	"redis.password",
+	"auth.jwt.secret",
+	"s3.secret_key",
+	"admin_password",
+	"rag.settings.api_key",
+}
+
+// isSecretKey reports whether key is one of secretKeyPaths.
+func isSecretKey(key string) bool {
+	for _, k := range secretKeyPaths {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// captureSecretRefs snapshots c's secret fields exactly as they currently read, with no
+// resolution applied. Called before resolveSecrets overwrites them in place, and by Restore to
+// capture the (already-unresolved) values out of a history revision.
+func captureSecretRefs(c *Config) map[string]string {
+	refs := map[string]string{
+		"pg.dsn":           string(c.PG.DSN),
+		"mq.nats.password": string(c.MQ.NATS.Password),
+		"redis.password":   string(c.Redis.Password),
+		"auth.jwt.secret":  string(c.Auth.JWT.Secret),
+		"s3.secret_key":    string(c.S3.SecretKey),
+		"admin_password":   string(c.AdminPassword),
+	}
+	if raw, ok := c.RAG.Settings["api_key"].(string); ok {
+		refs["rag.settings.api_key"] = raw
+	}
+	return refs
+}
+
+// resolveSecrets resolves every SecretRef field on c in place, plus the RAG provider's api_key
+// setting when present, and joins every resolver failure with its config key. It first snapshots
+// the pre-resolution references into c.secretRefs, so persistConfigFile can later write the
+// original reference back to disk instead of the resolved secret.
+func (c *Config) resolveSecrets() error {
+	c.secretRefs = captureSecretRefs(c)
+
+	var errs []error
+
+	resolve := func(key string, ref *SecretRef) {
+		value, err := ref.Resolve()
+		if err != nil {
+			errs = append(errs, &ConfigError{Key: key, Reason: err.Error()})
+			return
+		}
+		*ref = SecretRef(value)
+	}
+
+	resolve("pg.dsn", &c.PG.DSN)
+	resolve("mq.nats.password", &c.MQ.NATS.Password)
+	resolve("redis.password", &c.Redis.Password)
+	resolve("auth.jwt.secret", &c.Auth.JWT.Secret)
+	resolve("s3.secret_key", &c.S3.SecretKey)
+	resolve("admin_password", &c.AdminPassword)
+
+	if raw, ok := c.RAG.Settings["api_key"].(string); ok {
+		value, err := SecretRef(raw).Resolve()
+		if err != nil {
+			errs = append(errs, &ConfigError{Key: "rag.settings.api_key", Reason: err.Error()})
+		} else {
+			c.RAG.Settings["api_key"] = value
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PrintConfigMasked renders c as indented JSON with every secret field replaced by "***", for a
+// --print-config startup mode that never leaks resolved secrets into logs.
+func (c *Config) PrintConfigMasked() (string, error) {
+	settings := make(map[string]any, len(c.RAG.Settings))
+	for k, v := range c.RAG.Settings {
+		if k == "api_key" {
+			v = "***"
+		}
+		settings[k] = v
+	}
+
+	masked := struct {
+		Log           LogConfig
+		HTTP          HTTPConfig
+		AdminPassword SecretRef
+		PG            PGConfig
+		MQ            MQConfig
+		RAG           RAGConfig
+		Redis         RedisConfig
+		Auth          AuthConfig
+		S3            S3Config
+		CaddyAPI      string
+		SubnetPrefix  string
+	}{
+		Log:           c.Log,
+		HTTP:          c.HTTP,
+		AdminPassword: "***",
+		PG:            PGConfig{DSN: "***"},
+		MQ: MQConfig{
+			Type: c.MQ.Type,
+			NATS: NATSConfig{Server: c.MQ.NATS.Server, User: c.MQ.NATS.User, Password: "***"},
+		},
+		RAG:          RAGConfig{Provider: c.RAG.Provider, Settings: settings},
+		Redis:        RedisConfig{Addr: c.Redis.Addr, Password: "***"},
+		Auth:         AuthConfig{Type: c.Auth.Type, JWT: JWTConfig{Secret: "***"}},
+		S3:           S3Config{Endpoint: c.S3.Endpoint, AccessKey: c.S3.AccessKey, SecretKey: "***"},
+		CaddyAPI:     c.CaddyAPI,
+		SubnetPrefix: c.SubnetPrefix,
+	}
+
+	data, err := json.MarshalIndent(masked, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecretRef_Resolve(t *testing.T) {
+	t.Run("literal", func(t *testing.T) {
+		got, err := SecretRef("plain-value").Resolve()
+		if err != nil {
+			t.Fatalf("Resolve: %v", err)
+		}
+		if got != "plain-value" {
+			t.Errorf("Resolve() = %q, want %q", got, "plain-value")
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv("SECRETS_TEST_VALUE", "from-env")
+		got, err := SecretRef("env://SECRETS_TEST_VALUE").Resolve()
+		if err != nil {
+			t.Fatalf("Resolve: %v", err)
+		}
+		if got != "from-env" {
+			t.Errorf("Resolve() = %q, want %q", got, "from-env")
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "secret")
+		if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		got, err := SecretRef("file://" + path).Resolve()
+		if err != nil {
+			t.Fatalf("Resolve: %v", err)
+		}
+		if got != "from-file" {
+			t.Errorf("Resolve() = %q, want %q", got, "from-file")
+		}
+	})
+}
+
+func TestConfig_resolveSecrets(t *testing.T) {
+	t.Setenv("SECRETS_TEST_JWT", "jwt-from-env")
+
+	c := &Config{
+		Auth: AuthConfig{JWT: JWTConfig{Secret: "env://SECRETS_TEST_JWT"}},
+		RAG:  RAGConfig{Settings: map[string]any{"api_key": "literal-api-key"}},
+	}
+
+	if err := c.resolveSecrets(); err != nil {
+		t.Fatalf("resolveSecrets: %v", err)
+	}
+
+	if got := string(c.Auth.JWT.Secret); got != "jwt-from-env" {
+		t.Errorf("Auth.JWT.Secret = %q, want %q", got, "jwt-from-env")
+	}
+	if got := c.secretRefs["auth.jwt.secret"]; got != "env://SECRETS_TEST_JWT" {
+		t.Errorf("secretRefs[auth.jwt.secret] = %q, want the pre-resolution reference", got)
+	}
+	if got := c.secretRefs["rag.settings.api_key"]; got != "literal-api-key" {
+		t.Errorf("secretRefs[rag.settings.api_key] = %q, want %q", got, "literal-api-key")
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap/zapcore"
+)
+
+// ConfigError is a single, addressable config validation failure: which key was wrong and why.
+type ConfigError struct {
+	Key    string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("config: %s: %s", e.Key, e.Reason)
+}
+
+// Validate runs every sub-struct's own validation and joins the failures, so NewConfig can fail
+// fast with every offending key listed instead of surfacing as a panic deep inside a subsystem.
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Log.Validate(),
+		c.HTTP.Validate(),
+		c.PG.Validate(),
+		c.MQ.Validate(),
+		c.RAG.Validate(),
+		c.Redis.Validate(),
+		c.Auth.Validate(),
+		c.S3.Validate(),
+	)
+}
+
+func (l LogConfig) Validate() error {
+	level := zapcore.Level(l.Level)
+	if level < zapcore.DebugLevel || level > zapcore.FatalLevel {
+		return &ConfigError{Key: "log.level", Reason: fmt.Sprintf("%d is outside zap's valid level range", l.Level)}
+	}
+	return nil
+}
+
+func (h HTTPConfig) Validate() error {
+	if h.Port < 1 || h.Port > 65535 {
+		return &ConfigError{Key: "http.port", Reason: fmt.Sprintf("%d is not in 1..65535", h.Port)}
+	}
+	return nil
+}
+
+func (p PGConfig) Validate() error {
+	if _, err := pgx.ParseConfig(string(p.DSN)); err != nil {
+		return &ConfigError{Key: "pg.dsn", Reason: err.Error()}
+	}
+	return nil
+}
+
+func (m MQConfig) Validate() error {
+	u, err := url.Parse(m.NATS.Server)
+	if err != nil {
+		return &ConfigError{Key: "mq.nats.server", Reason: err.Error()}
+	}
+	switch u.Scheme {
+	case "nats", "tls", "ws":
+	default:
+		return &ConfigError{Key: "mq.nats.server", Reason: fmt.Sprintf("scheme %q is not one of nats, tls, ws", u.Scheme)}
+	}
+	return nil
+}
+
+func (r RAGConfig) Validate() error {
+	ragProvidersMu.RLock()
+	_, ok := ragProviders[r.Provider]
+	ragProvidersMu.RUnlock()
+	if !ok {
+		return &ConfigError{Key: "rag.provider", Reason: fmt.Sprintf("%q is not a registered rag provider", r.Provider)}
+	}
+	return nil
+}
+
+func (r RedisConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(r.Addr); err != nil {
+		return &ConfigError{Key: "redis.addr", Reason: err.Error()}
+	}
+	return nil
+}
+
+func (a AuthConfig) Validate() error {
+	if a.Type == "jwt" && a.JWT.Secret == "" {
+		return &ConfigError{Key: "auth.jwt.secret", Reason: "must be set when auth.type is \"jwt\""}
+	}
+	return nil
+}
+
+func (s S3Config) Validate() error {
+	if _, _, err := net.SplitHostPort(s.Endpoint); err != nil {
+		return &ConfigError{Key: "s3.endpoint", Reason: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import "testing"
+
+func validConfig() Config {
+	return Config{
+		Log:  LogConfig{Level: 0},
+		HTTP: HTTPConfig{Port: 8000},
+		PG:   PGConfig{DSN: "host=localhost user=panda-wiki password=x dbname=panda-wiki port=5432 sslmode=disable"},
+		MQ:   MQConfig{NATS: NATSConfig{Server: "nats://localhost:4222"}},
+		RAG:  RAGConfig{Provider: "ct"},
+		Redis: RedisConfig{
+			Addr: "localhost:6379",
+		},
+		Auth: AuthConfig{Type: "jwt", JWT: JWTConfig{Secret: "secret"}},
+		S3:   S3Config{Endpoint: "localhost:9000"},
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if c := validConfig(); c.Validate() != nil {
+		t.Fatalf("Validate() on a valid config = %v, want nil", c.Validate())
+	}
+}
+
+func TestConfig_Validate_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantKey string
+	}{
+		{"bad http port", func(c *Config) { c.HTTP.Port = 999999 }, "http.port"},
+		{"bad pg dsn", func(c *Config) { c.PG.DSN = "not a dsn" }, "pg.dsn"},
+		{"bad nats scheme", func(c *Config) { c.MQ.NATS.Server = "http://localhost:4222" }, "mq.nats.server"},
+		{"unknown rag provider", func(c *Config) { c.RAG.Provider = "nonexistent" }, "rag.provider"},
+		{"bad redis addr", func(c *Config) { c.Redis.Addr = "not-a-host-port" }, "redis.addr"},
+		{"missing jwt secret", func(c *Config) { c.Auth.JWT.Secret = "" }, "auth.jwt.secret"},
+		{"bad s3 endpoint", func(c *Config) { c.S3.Endpoint = "not-a-host-port" }, "s3.endpoint"},
+		{"bad log level", func(c *Config) { c.Log.Level = 100 }, "log.level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.mutate(&c)
+
+			err := c.Validate()
+			if err == nil {
+				t.Fatalf("Validate() = nil, want an error for key %q", tt.wantKey)
+			}
+
+			var found bool
+			for _, ce := range flattenConfigErrors(err) {
+				if ce.Key == tt.wantKey {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Validate() = %v, want an error for key %q", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+// flattenConfigErrors unwraps the errors.Join tree Validate returns into its *ConfigError leaves.
+func flattenConfigErrors(err error) []*ConfigError {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		if ce, ok := err.(*ConfigError); ok {
+			return []*ConfigError{ce}
+		}
+		return nil
+	}
+	var out []*ConfigError
+	for _, e := range joined.Unwrap() {
+		out = append(out, flattenConfigErrors(e)...)
+	}
+	return out
+}